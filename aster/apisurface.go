@@ -0,0 +1,315 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// IsExported reports whether t's name is exported. It follows the
+// ast.IsExported rule: an empty name (e.g. an anonymous composite
+// literal struct type) is never exported.
+func (t *StructType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *InterfaceType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *AliasType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *BasicType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *ChanType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *ListType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether t's name is exported.
+func (t *MapType) IsExported() bool { return isExportedName(t.Name()) }
+
+// IsExported reports whether fn's name is exported. An anonymous
+// FuncLit is never exported, regardless of its receiver (it has none).
+func (fn *FuncType) IsExported() bool { return isExportedName(fn.Name()) }
+
+func isExportedName(name string) bool {
+	return name != "" && ast.IsExported(name)
+}
+
+// ExportedTypes returns the exported TypeNodes declared across every
+// file in p, sorted by name.
+func (p *Package) ExportedTypes() []TypeNode {
+	var out []TypeNode
+	for _, f := range p.Files {
+		for _, t := range f.Types {
+			if t.IsExported() {
+				out = append(out, t)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// ExportedFuncs returns the exported, receiver-less functions declared
+// across every file in p, sorted by name. Exported methods are reached
+// through their receiver type instead (see TypeNode.Methods), since an
+// exported method on an unexported type is not part of the public API
+// surface.
+func (p *Package) ExportedFuncs() []FuncNode {
+	var out []FuncNode
+	for _, f := range p.Files {
+		for _, fn := range f.Funcs {
+			if _, hasRecv := fn.Recv(); hasRecv {
+				continue
+			}
+			if fn.IsExported() {
+				out = append(out, fn)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// ExportedFields returns s's exported fields.
+func (s *StructType) ExportedFields() []*Field {
+	var out []*Field
+	for _, fld := range s.Fields() {
+		if isExportedName(fld.Name) {
+			out = append(out, fld)
+		}
+	}
+	return out
+}
+
+// ExportedMethods returns t's exported methods.
+func (t *InterfaceType) ExportedMethods() []FuncNode {
+	var out []FuncNode
+	for _, m := range t.Methods() {
+		if m.IsExported() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// exportedMethodsOf returns t's exported methods, for the TypeNode kinds
+// (struct, alias, basic, ...) whose methods are bound externally via
+// File.bindMethods rather than declared inline, as an interface's are.
+func exportedMethodsOf(t TypeNode) []FuncNode {
+	var out []FuncNode
+	for _, m := range t.Methods() {
+		// An exported method on an unexported type is not part of the
+		// public API surface.
+		if t.IsExported() && m.IsExported() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// APISymbol is one exported symbol in a Module's public API surface.
+type APISymbol struct {
+	Package   string
+	Name      string
+	Kind      string // "type", "func", "method", "field"
+	Signature string
+}
+
+// APISet is a stable, serializable snapshot of a Module's exported API
+// surface, as produced by Module.APISurface.
+type APISet struct {
+	Symbols []APISymbol
+}
+
+// APISurface walks every package in m and returns a stable snapshot of
+// its exported types, funcs, fields and methods.
+func (m *Module) APISurface() APISet {
+	var set APISet
+	names := make([]string, 0, len(m.Pkgs))
+	for name := range m.Pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set.Symbols = append(set.Symbols, apiSymbolsForPackage(name, m.Pkgs[name])...)
+	}
+	sort.Slice(set.Symbols, func(i, j int) bool {
+		a, b := set.Symbols[i], set.Symbols[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Name < b.Name
+	})
+	return set
+}
+
+func apiSymbolsForPackage(pkgName string, pkg *Package) []APISymbol {
+	var out []APISymbol
+	for _, t := range pkg.ExportedTypes() {
+		out = append(out, APISymbol{Package: pkgName, Name: t.Name(), Kind: "type", Signature: typeSignature(pkg, t)})
+		for _, meth := range exportedMethodsOf(t) {
+			out = append(out, APISymbol{
+				Package:   pkgName,
+				Name:      t.Name() + "." + meth.Name(),
+				Kind:      "method",
+				Signature: funcSignature(meth),
+			})
+		}
+		if st, ok := t.(*StructType); ok {
+			for _, fld := range st.ExportedFields() {
+				out = append(out, APISymbol{
+					Package:   pkgName,
+					Name:      t.Name() + "." + fld.Name,
+					Kind:      "field",
+					Signature: fld.TypeName,
+				})
+			}
+		}
+	}
+	for _, fn := range pkg.ExportedFuncs() {
+		out = append(out, APISymbol{Package: pkgName, Name: fn.Name(), Kind: "func", Signature: funcSignature(fn)})
+	}
+	return out
+}
+
+// typeSignature renders t's full declaration ("type Name ...") via
+// File.TryFormat, so that a change to its underlying type (an alias
+// retargeted, a struct's fields changed, ...) is visible in the
+// signature even when its name stays the same.
+func typeSignature(pkg *Package, t TypeNode) string {
+	f, ok := fileOwning(pkg, t)
+	if !ok {
+		return t.Name()
+	}
+	path, exact := f.PathEnclosingInterval(t.Pos(), t.End())
+	if !exact || len(path) == 0 {
+		return t.Name()
+	}
+	return "type " + t.Name() + " " + f.TryFormat(path[0], t.Name())
+}
+
+// fileOwning returns the File in pkg that collected t, so its FileSet
+// can be used to format t's underlying AST node.
+func fileOwning(pkg *Package, t TypeNode) (*File, bool) {
+	for _, f := range pkg.Files {
+		if f.Types[t.Pos()] == t {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// funcSignature renders fn's signature from its already-resolved
+// receiver, parameter and result FuncFields, so it can be compared
+// across snapshots without needing fn's originating File.
+func funcSignature(fn FuncNode) string {
+	var b strings.Builder
+	b.WriteString("func ")
+	if recv, ok := fn.Recv(); ok {
+		b.WriteString("(")
+		b.WriteString(recv.TypeName)
+		b.WriteString(") ")
+	}
+	b.WriteString(fn.Name())
+	b.WriteString("(")
+	writeFieldTypes(&b, fn.Params())
+	b.WriteString(")")
+	results := fn.Results()
+	switch len(results) {
+	case 0:
+	case 1:
+		b.WriteString(" ")
+		b.WriteString(results[0].TypeName)
+	default:
+		b.WriteString(" (")
+		writeFieldTypes(&b, results)
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+func writeFieldTypes(b *strings.Builder, fields []*FuncField) {
+	for i, fld := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fld.TypeName)
+	}
+}
+
+// APIChange describes one difference between two API snapshots of the
+// same module taken at different points in time.
+type APIChange struct {
+	Package  string
+	Symbol   string
+	Kind     string // "added", "removed", "changed"
+	Breaking bool
+	Before   string
+	After    string
+}
+
+// DiffAPI compares two API snapshots and reports every symbol that was
+// added, removed, or whose signature changed. Removed and changed
+// symbols are classified as breaking; added symbols are additive.
+func DiffAPI(old, new APISet) []APIChange {
+	oldIdx := indexAPISymbols(old)
+	newIdx := indexAPISymbols(new)
+	var changes []APIChange
+	for key, o := range oldIdx {
+		n, ok := newIdx[key]
+		if !ok {
+			changes = append(changes, APIChange{
+				Package: o.Package, Symbol: o.Name, Kind: "removed",
+				Breaking: true, Before: o.Signature,
+			})
+			continue
+		}
+		if o.Signature != n.Signature {
+			changes = append(changes, APIChange{
+				Package: o.Package, Symbol: o.Name, Kind: "changed",
+				Breaking: true, Before: o.Signature, After: n.Signature,
+			})
+		}
+	}
+	for key, n := range newIdx {
+		if _, ok := oldIdx[key]; !ok {
+			changes = append(changes, APIChange{
+				Package: n.Package, Symbol: n.Name, Kind: "added",
+				Breaking: false, After: n.Signature,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Symbol < b.Symbol
+	})
+	return changes
+}
+
+func indexAPISymbols(set APISet) map[string]APISymbol {
+	idx := make(map[string]APISymbol, len(set.Symbols))
+	for _, s := range set.Symbols {
+		idx[s.Package+"\x00"+s.Kind+"\x00"+s.Name] = s
+	}
+	return idx
+}
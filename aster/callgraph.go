@@ -0,0 +1,339 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// UnresolvedCall is a call expression the CallGraph could not resolve to
+// a FuncNode within the module, e.g. a call into an external package or
+// dynamic dispatch through an interface.
+type UnresolvedCall struct {
+	Call   *ast.CallExpr
+	Caller FuncNode
+}
+
+// CallGraph is a cross-package call graph built over a Module: an edge
+// runs from a FuncNode to every FuncNode it calls that aster was able to
+// resolve within the module.
+type CallGraph struct {
+	callees    map[FuncNode][]FuncNode
+	callers    map[FuncNode][]FuncNode
+	unresolved []*UnresolvedCall
+}
+
+// Callees returns the funcs that fn calls, directly, within the module.
+func (g *CallGraph) Callees(fn FuncNode) []FuncNode {
+	return g.callees[fn]
+}
+
+// Callers returns the funcs within the module that call fn, directly.
+func (g *CallGraph) Callers(fn FuncNode) []FuncNode {
+	return g.callers[fn]
+}
+
+// UnresolvedCalls returns every call site the graph could not resolve to
+// a FuncNode in the module, so callers can decide how to treat them
+// (external package, interface dispatch, etc).
+func (g *CallGraph) UnresolvedCalls() []*UnresolvedCall {
+	return g.unresolved
+}
+
+// CallGraph walks every FuncNode in the module and resolves its calls to
+// other FuncNodes in the module, building the full call graph.
+func (m *Module) CallGraph() *CallGraph {
+	g := &CallGraph{
+		callees: make(map[FuncNode][]FuncNode),
+		callers: make(map[FuncNode][]FuncNode),
+	}
+	for _, pkg := range m.Pkgs {
+		for _, f := range pkg.Files {
+			f.addCallEdges(g)
+		}
+	}
+	return g
+}
+
+// addCallEdges walks f's AST for call expressions, resolves each one to
+// its enclosing FuncNode and, where possible, to the FuncNode it calls,
+// recording an edge in g (or an UnresolvedCall when it can't).
+func (f *File) addCallEdges(g *CallGraph) {
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		caller, body, ok := f.enclosingFuncAndBody(call.Pos())
+		if !ok {
+			return true
+		}
+		callee, ok := f.resolveCall(caller, body, call)
+		if !ok {
+			g.unresolved = append(g.unresolved, &UnresolvedCall{Call: call, Caller: caller})
+			return true
+		}
+		g.callees[caller] = append(g.callees[caller], callee)
+		g.callers[callee] = append(g.callers[callee], caller)
+		return true
+	})
+}
+
+// enclosingFuncAndBody returns both the FuncNode enclosing pos and the
+// *ast.BlockStmt of the underlying FuncDecl/FuncLit, so callers can
+// inspect the caller's local declarations as well as its signature.
+func (f *File) enclosingFuncAndBody(pos token.Pos) (fn FuncNode, body *ast.BlockStmt, found bool) {
+	path, _ := f.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		switch x := n.(type) {
+		case *ast.FuncDecl:
+			if fn, found = f.Funcs[n.Pos()]; found {
+				return fn, x.Body, true
+			}
+		case *ast.FuncLit:
+			if fn, found = f.Funcs[n.Pos()]; found {
+				return fn, x.Body, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// resolveCall resolves call, made from within caller, to the FuncNode it
+// invokes, if that FuncNode lives in the same module.
+func (f *File) resolveCall(caller FuncNode, body *ast.BlockStmt, call *ast.CallExpr) (callee FuncNode, found bool) {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return f.resolveFuncIdent(fun.Name)
+	case *ast.SelectorExpr:
+		if callee, found = f.resolveMethodSelector(caller, body, fun); found {
+			return callee, true
+		}
+		return f.resolvePackageFunc(fun)
+	default:
+		// e.g. an immediately invoked func literal or a call through a
+		// parenthesized/converted expression: not worth resolving.
+		return nil, false
+	}
+}
+
+// resolveFuncIdent resolves a bare identifier call, such as Foo(), to a
+// receiver-less FuncNode named name somewhere in the file's package.
+func (f *File) resolveFuncIdent(name string) (FuncNode, bool) {
+	if f.pkg == nil {
+		return lookupFuncInFile(f, name)
+	}
+	for _, v := range f.pkg.Files {
+		if fn, ok := lookupFuncInFile(v, name); ok {
+			return fn, true
+		}
+	}
+	return nil, false
+}
+
+// resolvePackageFunc resolves a qualified call pkg.Foo(), where pkg is
+// the local name of an imported package in the same module, to the
+// receiver-less FuncNode named Foo in that package.
+func (f *File) resolvePackageFunc(sel *ast.SelectorExpr) (FuncNode, bool) {
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	pkgs, ok := f.LookupPackages(id.Name)
+	if !ok {
+		return nil, false
+	}
+	for _, p := range pkgs {
+		for _, v := range p.Files {
+			if fn, ok := lookupFuncInFile(v, sel.Sel.Name); ok {
+				return fn, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func lookupFuncInFile(f *File, name string) (FuncNode, bool) {
+	for _, fn := range f.Funcs {
+		if _, hasRecv := fn.Recv(); hasRecv {
+			continue
+		}
+		if fn.Name() == name {
+			return fn, true
+		}
+	}
+	return nil, false
+}
+
+// resolveMethodSelector resolves a method-call selector such as x.M() or
+// x.field.M(), where the static type of x (or x.field) is known from
+// caller's receiver, parameters, local declarations, or the fields of
+// one of those, to the matching method on that type.
+func (f *File) resolveMethodSelector(caller FuncNode, body *ast.BlockStmt, sel *ast.SelectorExpr) (FuncNode, bool) {
+	typeName, ok := f.staticTypeOfExpr(caller, body, sel.X, sel.Pos())
+	if !ok {
+		return nil, false
+	}
+	t, ok := f.LookupTypeInModule(typeName)
+	if !ok {
+		return nil, false
+	}
+	for _, m := range t.Methods() {
+		if m.Name() == sel.Sel.Name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// staticTypeOfExpr returns the declared type name of expr as seen from
+// within caller at pos: an identifier resolves via staticTypeOf, and a
+// selector expression (base.Field) resolves by looking up Field among
+// the exported and unexported fields of base's own static type.
+func (f *File) staticTypeOfExpr(caller FuncNode, body *ast.BlockStmt, expr ast.Expr, pos token.Pos) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return f.staticTypeOf(caller, body, x.Name, pos)
+	case *ast.SelectorExpr:
+		baseType, ok := f.staticTypeOfExpr(caller, body, x.X, pos)
+		if !ok {
+			return "", false
+		}
+		t, ok := f.LookupTypeInModule(baseType)
+		if !ok {
+			return "", false
+		}
+		st, ok := t.(*StructType)
+		if !ok {
+			return "", false
+		}
+		for _, fld := range st.Fields() {
+			if fld.Name == x.Sel.Name {
+				return fld.TypeName, true
+			}
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
+
+// staticTypeOf returns the declared type name of the identifier name as
+// seen from within fn at pos: its receiver, one of its parameters, or a
+// local variable declared (via "var" or ":=") in the innermost block
+// that lexically encloses pos (or one of that block's enclosing
+// blocks), stopping at body. Scanning only the blocks actually on the
+// path to pos, innermost first, keeps sibling blocks that redeclare the
+// same name (e.g. the two arms of an if/else) from shadowing one
+// another.
+func (f *File) staticTypeOf(fn FuncNode, body *ast.BlockStmt, name string, pos token.Pos) (typeName string, found bool) {
+	if recv, ok := fn.Recv(); ok && recv.Name == name {
+		return recv.TypeName, true
+	}
+	for _, p := range fn.Params() {
+		if p.Name == name {
+			return p.TypeName, true
+		}
+	}
+	if body == nil || pos == token.NoPos {
+		return "", false
+	}
+	path, _ := f.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			continue
+		}
+		if typeName, found = f.declaredTypeInBlock(block, name, pos); found {
+			return typeName, true
+		}
+		if block == body {
+			break
+		}
+	}
+	return "", false
+}
+
+// declaredTypeInBlock scans block's own statements (not nested blocks,
+// which the caller visits separately as it walks outward) for a "var"
+// or ":=" declaration of name that occurs before pos, returning the
+// last such declaration's inferred type.
+func (f *File) declaredTypeInBlock(block *ast.BlockStmt, name string, pos token.Pos) (typeName string, found bool) {
+	for _, stmt := range block.List {
+		if stmt.Pos() >= pos {
+			break
+		}
+		switch d := stmt.(type) {
+		case *ast.DeclStmt:
+			gen, ok := d.Decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || vs.Type == nil {
+					continue
+				}
+				for _, id := range vs.Names {
+					if id.Name == name {
+						typeName, found = f.TryFormat(vs.Type), true
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if d.Tok != token.DEFINE {
+				continue
+			}
+			for i, lhs := range d.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok || id.Name != name || i >= len(d.Rhs) {
+					continue
+				}
+				if tn, ok := f.inferredTypeName(d.Rhs[i]); ok {
+					typeName, found = tn, true
+				}
+			}
+		}
+	}
+	return typeName, found
+}
+
+// inferredTypeName makes a best-effort guess at the static type of a
+// short variable declaration's right-hand side: a composite literal's
+// own type, a pointer to one, or the type named by new(T) or a
+// conversion/constructor call T(...).
+func (f *File) inferredTypeName(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.CompositeLit:
+		if x.Type != nil {
+			return f.TryFormat(x.Type), true
+		}
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			if tn, ok := f.inferredTypeName(x.X); ok {
+				return "*" + tn, true
+			}
+		}
+	case *ast.CallExpr:
+		if id, ok := x.Fun.(*ast.Ident); ok {
+			if id.Name == "new" && len(x.Args) == 1 {
+				return "*" + f.TryFormat(x.Args[0]), true
+			}
+			return id.Name, true
+		}
+	}
+	return "", false
+}
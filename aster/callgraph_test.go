@@ -0,0 +1,66 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"testing"
+)
+
+// TestStaticTypeOf_SiblingBlocksDontShadow reproduces a handler-dispatch
+// pattern where two sibling blocks each declare a same-named local with
+// a different type: the call in each branch must resolve against that
+// branch's own declaration, not whichever one appears first in the
+// function body.
+func TestStaticTypeOf_SiblingBlocksDontShadow(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func Handle(which string) {
+	if which == "a" {
+		h := &AHandler{}
+		h.Do()
+	} else {
+		h := &BHandler{}
+		h.Do()
+	}
+}
+`)
+	fd := f.File.Decls[0].(*ast.FuncDecl)
+	var calls []*ast.CallExpr
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok {
+			calls = append(calls, c)
+		}
+		return true
+	})
+	if len(calls) != 2 {
+		t.Fatalf("found %d calls, want 2", len(calls))
+	}
+	fn, ok := f.Funcs[fd.Pos()]
+	if !ok {
+		t.Fatalf("Handle not found in f.Funcs")
+	}
+	want := []string{"*AHandler", "*BHandler"}
+	for i, call := range calls {
+		sel := call.Fun.(*ast.SelectorExpr)
+		typeName, ok := f.staticTypeOf(fn, fd.Body, sel.X.(*ast.Ident).Name, sel.Pos())
+		if !ok {
+			t.Fatalf("call %d: staticTypeOf() not found", i)
+		}
+		if typeName != want[i] {
+			t.Errorf("call %d: staticTypeOf() = %q, want %q", i, typeName, want[i])
+		}
+	}
+}
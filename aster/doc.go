@@ -0,0 +1,92 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *StructType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *StructType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *InterfaceType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *InterfaceType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *AliasType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *AliasType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *BasicType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *BasicType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *ChanType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *ChanType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *ListType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *ListType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders t's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (t *MapType) DocMarkdown() string { return RenderDocMarkdown(t.Doc()) }
+
+// DocHTML renders t's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (t *MapType) DocHTML() string { return RenderDocHTML(t.Doc()) }
+
+// DocMarkdown renders fn's doc comment as Markdown. See RenderDocMarkdown
+// for the rendering rules.
+func (fn *FuncType) DocMarkdown() string { return RenderDocMarkdown(fn.Doc()) }
+
+// DocHTML renders fn's doc comment as HTML. See RenderDocHTML for the
+// rendering rules.
+func (fn *FuncType) DocHTML() string { return RenderDocHTML(fn.Doc()) }
+
+// DocMarkdown renders p's package doc comment as Markdown. See
+// RenderDocMarkdown for the rendering rules.
+func (p *Package) DocMarkdown() string { return RenderDocMarkdown(p.Doc()) }
+
+// DocHTML renders p's package doc comment as HTML. See RenderDocHTML for
+// the rendering rules.
+func (p *Package) DocHTML() string { return RenderDocHTML(p.Doc()) }
+
+// Synopsis returns the first full sentence of doc, trimmed to a single
+// line, the classic one-line summary godoc shows in package and symbol
+// listings.
+func (p *Package) Synopsis(doc string) string { return synopsis(doc) }
@@ -0,0 +1,203 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// docBlockKind classifies one paragraph of a doc comment for rendering,
+// following the classic godoc comment grammar.
+type docBlockKind int
+
+const (
+	docText docBlockKind = iota
+	docCode
+	docHeading
+)
+
+type docBlock struct {
+	kind  docBlockKind
+	lines []string
+}
+
+// headingPattern matches a paragraph-start line that reads as a heading:
+// it begins with an uppercase letter and carries no sentence-ending
+// punctuation.
+var headingPattern = regexp.MustCompile(`^[A-Z][^.!?]*$`)
+
+// splitDocBlocks splits a doc comment's text into paragraphs and
+// classifies each one as a heading, a preformatted code block (any
+// paragraph whose lines are all indented), or ordinary text.
+func splitDocBlocks(text string) []docBlock {
+	var blocks []docBlock
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		blocks = append(blocks, docBlock{kind: classifyParagraph(para), lines: para})
+		para = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		para = append(para, line)
+	}
+	flush()
+	return blocks
+}
+
+func classifyParagraph(para []string) docBlockKind {
+	indented := true
+	for _, l := range para {
+		if !strings.HasPrefix(l, " ") && !strings.HasPrefix(l, "\t") {
+			indented = false
+			break
+		}
+	}
+	if indented {
+		return docCode
+	}
+	if len(para) == 1 && headingPattern.MatchString(strings.TrimSpace(para[0])) {
+		return docHeading
+	}
+	return docText
+}
+
+// mdLinkPattern recognizes an already-written Markdown link, so
+// auto-linking doesn't mangle one a doc comment author wrote by hand.
+var mdLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+
+// bareURLPattern recognizes a bare http(s) URL to auto-link.
+var bareURLPattern = regexp.MustCompile(`https?://[^\s)\]]+`)
+
+// docLink is a (display text, href) pair extracted from a doc comment.
+type docLink struct{ text, href string }
+
+// extractLinks pulls both Markdown-style and bare-URL links out of s,
+// replacing each with a NUL-delimited placeholder so the surrounding
+// renderer (Markdown or HTML) can re-insert them in its own syntax
+// without re-matching a URL that's already part of a link.
+func extractLinks(s string) (string, []docLink) {
+	var links []docLink
+	s = mdLinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdLinkPattern.FindStringSubmatch(m)
+		links = append(links, docLink{text: sub[1], href: sub[2]})
+		return fmt.Sprintf("\x00%d\x00", len(links)-1)
+	})
+	s = bareURLPattern.ReplaceAllStringFunc(s, func(m string) string {
+		links = append(links, docLink{text: m, href: m})
+		return fmt.Sprintf("\x00%d\x00", len(links)-1)
+	})
+	return s, links
+}
+
+func placeholder(i int) string { return fmt.Sprintf("\x00%d\x00", i) }
+
+// RenderDocMarkdown renders a doc comment's text as Markdown, per the
+// godoc comment grammar: blank-line-separated paragraphs, indented
+// paragraphs as fenced code blocks, single-line paragraphs that look
+// like a title as "## " headings, and bare/Markdown-style links
+// auto-linked.
+func RenderDocMarkdown(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var out []string
+	for _, b := range splitDocBlocks(doc.Text()) {
+		switch b.kind {
+		case docCode:
+			out = append(out, "```\n"+strings.Join(b.lines, "\n")+"\n```")
+		case docHeading:
+			out = append(out, "## "+linkifyMarkdown(strings.TrimSpace(b.lines[0])))
+		default:
+			out = append(out, linkifyMarkdown(strings.Join(b.lines, "\n")))
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func linkifyMarkdown(s string) string {
+	s, links := extractLinks(s)
+	for i, l := range links {
+		s = strings.Replace(s, placeholder(i), fmt.Sprintf("[%s](%s)", l.text, l.href), 1)
+	}
+	return s
+}
+
+// RenderDocHTML renders a doc comment's text as HTML, using the same
+// paragraph/code/heading classification as RenderDocMarkdown.
+func RenderDocHTML(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var buf strings.Builder
+	for _, b := range splitDocBlocks(doc.Text()) {
+		switch b.kind {
+		case docCode:
+			buf.WriteString("<pre>")
+			buf.WriteString(html.EscapeString(strings.Join(b.lines, "\n")))
+			buf.WriteString("</pre>\n")
+		case docHeading:
+			buf.WriteString("<h3>")
+			buf.WriteString(linkifyHTML(strings.TrimSpace(b.lines[0])))
+			buf.WriteString("</h3>\n")
+		default:
+			buf.WriteString("<p>")
+			buf.WriteString(linkifyHTML(strings.Join(b.lines, "\n")))
+			buf.WriteString("</p>\n")
+		}
+	}
+	return buf.String()
+}
+
+func linkifyHTML(s string) string {
+	s, links := extractLinks(s)
+	s = html.EscapeString(s)
+	for i, l := range links {
+		s = strings.Replace(s, placeholder(i), fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(l.href), html.EscapeString(l.text)), 1)
+	}
+	return s
+}
+
+// synopsis returns the first full sentence of doc, the classic godoc
+// one-line package/symbol summary.
+func synopsis(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return ""
+	}
+	// Stop at the first blank line (end of the lead paragraph).
+	if i := strings.Index(doc, "\n\n"); i >= 0 {
+		doc = doc[:i]
+	}
+	doc = strings.Join(strings.Fields(doc), " ")
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '.', '!', '?':
+			if i == len(doc)-1 || doc[i+1] == ' ' {
+				return doc[:i+1]
+			}
+		}
+	}
+	return doc
+}
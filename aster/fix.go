@@ -0,0 +1,261 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+)
+
+// Fix is a named, self-contained code migration, modeled on the gofix /
+// aefix rule shape: Apply inspects and rewrites a single file in place
+// and reports whether it changed anything.
+type Fix struct {
+	Name string
+	Date string
+	Desc string
+	// Apply rewrites f in place and reports whether it made any change.
+	Apply func(f *File) (changed bool, err error)
+}
+
+var fixRegistry = map[string]Fix{}
+var fixOrder []string
+
+// Register adds fx to the set of fixes known to ApplyFixes. It panics if
+// a fix with the same name is already registered; Register is meant to
+// be called from init, where a duplicate is a programming error.
+func Register(fx Fix) {
+	if fx.Name == "" {
+		panic("aster: Fix must have a Name")
+	}
+	if _, dup := fixRegistry[fx.Name]; dup {
+		panic("aster: fix already registered: " + fx.Name)
+	}
+	fixRegistry[fx.Name] = fx
+	fixOrder = append(fixOrder, fx.Name)
+}
+
+func fixesByName(names []string) ([]Fix, error) {
+	if len(names) == 0 {
+		fixes := make([]Fix, 0, len(fixOrder))
+		for _, name := range fixOrder {
+			fixes = append(fixes, fixRegistry[name])
+		}
+		return fixes, nil
+	}
+	fixes := make([]Fix, 0, len(names))
+	for _, name := range names {
+		fx, ok := fixRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("aster: no such fix: %s", name)
+		}
+		fixes = append(fixes, fx)
+	}
+	return fixes, nil
+}
+
+// FixChange records that applying a Fix changed a File.
+type FixChange struct {
+	Fix  string
+	File *File
+	// Diff is the changed file's source after the fix, re-emitted via
+	// File.Format so it can be reviewed or written back out.
+	Diff string
+}
+
+// Report is the result of running one or more Fixes: every file each
+// one actually changed.
+type Report struct {
+	Changes []FixChange
+}
+
+// ApplyFixes runs the named fixes against f, in the given order (or
+// every registered fix, in registration order, if names is empty), and
+// reports which of them changed f.
+func (f *File) ApplyFixes(names ...string) (Report, error) {
+	var report Report
+	fixes, err := fixesByName(names)
+	if err != nil {
+		return report, err
+	}
+	for _, fx := range fixes {
+		changed, err := fx.Apply(f)
+		if err != nil {
+			return report, fmt.Errorf("aster: fix %s: %w", fx.Name, err)
+		}
+		if !changed {
+			continue
+		}
+		report.Changes = append(report.Changes, FixChange{
+			Fix:  fx.Name,
+			File: f,
+			Diff: f.TryFormat(f.File),
+		})
+	}
+	return report, nil
+}
+
+// ApplyFixes runs the named fixes (or every registered fix, if names is
+// empty) against every file in the module, and reports which files each
+// one changed.
+func (m *Module) ApplyFixes(names ...string) (Report, error) {
+	var report Report
+	for _, pkg := range m.Pkgs {
+		for _, f := range pkg.Files {
+			sub, err := f.ApplyFixes(names...)
+			if err != nil {
+				return report, err
+			}
+			report.Changes = append(report.Changes, sub.Changes...)
+		}
+	}
+	return report, nil
+}
+
+// Walk traverses f's AST in depth-first order. pre is called before a
+// node's children are visited and may return false to skip them; post,
+// if non-nil, is called after a node's children (or the skipped
+// children) have been visited. Either callback may be nil.
+func (f *File) Walk(pre, post func(ast.Node) bool) {
+	var visit func(ast.Node)
+	visit = func(n ast.Node) {
+		if n == nil {
+			return
+		}
+		descend := true
+		if pre != nil {
+			descend = pre(n)
+		}
+		if descend {
+			for _, c := range childrenOf(n) {
+				visit(c)
+			}
+		}
+		if post != nil {
+			post(n)
+		}
+	}
+	visit(f.File)
+}
+
+// Replace rewrites the first occurrence of old, found anywhere in f's
+// AST, to new, and reports whether it found (and replaced) old. It
+// works by locating the exported struct field, interface slot, or slice
+// element that holds old and assigning new in its place, so callers
+// don't need to track parents themselves.
+func (f *File) Replace(old, new ast.Node) bool {
+	return replaceNode(reflect.ValueOf(f.File), old, new)
+}
+
+// objType and scopeType mark the two fields go/ast attaches to the tree
+// for name resolution, *ast.Ident.Obj and *ast.File.Scope (and
+// *ast.Object.Decl/Data, which point right back at the declaring
+// node). ast.Walk never descends into them, and neither must
+// replaceNode: an Ident's Obj.Decl for "x" in "x := 1" is the very
+// *ast.AssignStmt already on the call stack, so following it recurses
+// forever.
+var (
+	objType   = reflect.TypeOf((*ast.Object)(nil))
+	scopeType = reflect.TypeOf((*ast.Scope)(nil))
+)
+
+func replaceNode(v reflect.Value, old, new ast.Node) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() || v.Type() == objType || v.Type() == scopeType {
+			return false
+		}
+		return replaceNode(v.Elem(), old, new)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue // unexported: not part of the public AST shape
+			}
+			if ft := t.Field(i).Type; ft == objType || ft == scopeType {
+				continue // name-resolution metadata, not a syntactic child
+			}
+			fv := v.Field(i)
+			if fv.CanSet() && fv.CanInterface() {
+				if n, ok := fv.Interface().(ast.Node); ok && n == old {
+					fv.Set(reflect.ValueOf(new))
+					return true
+				}
+			}
+			if replaceNode(fv, old, new) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.CanInterface() {
+				if n, ok := elem.Interface().(ast.Node); ok && n == old {
+					elem.Set(reflect.ValueOf(new))
+					return true
+				}
+			}
+			if replaceNode(elem, old, new) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InsertBefore inserts decl into f's declarations immediately before
+// anchor, and reports whether anchor was found.
+func (f *File) InsertBefore(anchor, decl ast.Decl) bool {
+	return f.insertDecl(anchor, decl, 0)
+}
+
+// InsertAfter inserts decl into f's declarations immediately after
+// anchor, and reports whether anchor was found.
+func (f *File) InsertAfter(anchor, decl ast.Decl) bool {
+	return f.insertDecl(anchor, decl, 1)
+}
+
+func (f *File) insertDecl(anchor, decl ast.Decl, offset int) bool {
+	for i, d := range f.File.Decls {
+		if d != anchor {
+			continue
+		}
+		at := i + offset
+		decls := make([]ast.Decl, 0, len(f.File.Decls)+1)
+		decls = append(decls, f.File.Decls[:at]...)
+		decls = append(decls, decl)
+		decls = append(decls, f.File.Decls[at:]...)
+		f.File.Decls = decls
+		return true
+	}
+	return false
+}
+
+// RenameIdent renames every *ast.Ident in f that resolves to obj (i.e.
+// every reference to, and the declaration of, the same object), which
+// respects lexical scoping since distinct objects never share an
+// *ast.Object even if they have the same name.
+func (f *File) RenameIdent(obj *ast.Object, newName string) {
+	if obj == nil {
+		return
+	}
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Obj == obj {
+			id.Name = newName
+		}
+		return true
+	})
+}
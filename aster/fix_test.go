@@ -0,0 +1,255 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+func TestWalk_PreAndPost(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() int { return 1 }
+`)
+	var pre, post int
+	f.Walk(
+		func(n ast.Node) bool { pre++; return true },
+		func(n ast.Node) bool { post++; return true },
+	)
+	if pre == 0 || pre != post {
+		t.Errorf("pre = %d, post = %d, want equal and non-zero", pre, post)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() int { return 1 }
+`)
+	fd := f.File.Decls[0].(*ast.FuncDecl)
+	ret := fd.Body.List[0].(*ast.ReturnStmt)
+	old := ret.Results[0]
+	if !f.Replace(old, ast.NewIdent("n")) {
+		t.Fatalf("Replace() = false, want true")
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, "return n") {
+		t.Errorf("Replace() didn't rewrite return value:\n%s", out)
+	}
+}
+
+// TestReplace_LocalVarInScope guards against stack overflow: an
+// *ast.Ident's Obj.Decl (and *ast.Object.Data/Type) can point right back
+// at an ancestor already on the call stack, e.g. the "x" in "x := 1"
+// resolves its own Obj.Decl to that very *ast.AssignStmt. Replace must
+// not follow those fields.
+func TestReplace_LocalVarInScope(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	x := 1
+	doWork(placeholder(), x)
+}
+`)
+	fd := f.File.Decls[0].(*ast.FuncDecl)
+	var call *ast.CallExpr
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok {
+			if id, ok := c.Fun.(*ast.Ident); ok && id.Name == "placeholder" {
+				call = c
+			}
+		}
+		return true
+	})
+	if call == nil {
+		t.Fatalf("placeholder() call not found")
+	}
+	if !f.Replace(call, ast.NewIdent("ctx")) {
+		t.Fatalf("Replace() = false, want true")
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, "doWork(ctx, x)") {
+		t.Errorf("Replace() didn't rewrite the call:\n%s", out)
+	}
+}
+
+func TestInsertBeforeAfter(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func A() {}
+func C() {}
+`)
+	anchor := f.File.Decls[1]
+	b := &ast.FuncDecl{
+		Name: ast.NewIdent("B"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{},
+	}
+	if !f.InsertBefore(anchor, b) {
+		t.Fatalf("InsertBefore() = false, want true")
+	}
+	d := &ast.FuncDecl{
+		Name: ast.NewIdent("D"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{},
+	}
+	if !f.InsertAfter(anchor, d) {
+		t.Fatalf("InsertAfter() = false, want true")
+	}
+	var names []string
+	for _, decl := range f.File.Decls {
+		names = append(names, decl.(*ast.FuncDecl).Name.Name)
+	}
+	want := "A B C D"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("decl order = %q, want %q", got, want)
+	}
+}
+
+func TestRenameIdent(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	x := 1
+	y := x + 1
+	_ = y
+}
+`)
+	var target *ast.Object
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "x" && id.Obj != nil {
+			target = id.Obj
+		}
+		return true
+	})
+	if target == nil {
+		t.Fatalf("couldn't find *ast.Object for x")
+	}
+	f.RenameIdent(target, "renamed")
+	out := formatTestFile(t, f)
+	if strings.Contains(out, "x") {
+		t.Errorf("RenameIdent() left old name behind:\n%s", out)
+	}
+	if !strings.Contains(out, "renamed := 1") || !strings.Contains(out, "renamed + 1") {
+		t.Errorf("RenameIdent() didn't rename all references:\n%s", out)
+	}
+}
+
+func TestApplyFixes_ContextPkgPath(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+import "golang.org/x/net/context"
+
+func F(c context.Context) {
+	_ = context.Background()
+}
+`)
+	report, err := f.ApplyFixes("contextpkgpath")
+	if err != nil {
+		t.Fatalf("ApplyFixes() error = %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Fix != "contextpkgpath" {
+		t.Fatalf("report.Changes = %+v, want one contextpkgpath change", report.Changes)
+	}
+	out := formatTestFile(t, f)
+	if strings.Contains(out, "golang.org/x/net/context") {
+		t.Errorf("old import path survived fix:\n%s", out)
+	}
+	if !strings.Contains(out, `"context"`) {
+		t.Errorf("new import path missing from fix:\n%s", out)
+	}
+	if !strings.Contains(out, "context.Context") || !strings.Contains(out, "context.Background()") {
+		t.Errorf("qualified references lost during fix:\n%s", out)
+	}
+}
+
+func TestApplyFixes_AddCtxParam(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	doWork(context.TODO())
+}
+`)
+	report, err := f.ApplyFixes("addctxparam")
+	if err != nil {
+		t.Fatalf("ApplyFixes() error = %v", err)
+	}
+	if len(report.Changes) != 1 {
+		t.Fatalf("report.Changes = %+v, want one change", report.Changes)
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, "func F(ctx context.Context)") {
+		t.Errorf("addctxparam didn't add the parameter:\n%s", out)
+	}
+	if !strings.Contains(out, "doWork(ctx)") {
+		t.Errorf("addctxparam didn't thread ctx through the placeholder call:\n%s", out)
+	}
+	if strings.Contains(out, "context.TODO") {
+		t.Errorf("placeholder call survived:\n%s", out)
+	}
+
+	again, err := f.ApplyFixes("addctxparam")
+	if err != nil {
+		t.Fatalf("second ApplyFixes() error = %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("second ApplyFixes() changed %+v, want no-op once the ctx param already exists", again.Changes)
+	}
+}
+
+// TestApplyFixes_AddCtxParam_LocalVarInScope covers the combination that
+// previously crashed: the target function has a local variable in
+// scope, whose Ident.Obj.Decl cycles back through Replace's reflective
+// walk if that walk isn't pruned at Obj/Scope boundaries.
+func TestApplyFixes_AddCtxParam_LocalVarInScope(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	x := 1
+	doWork(context.TODO(), x)
+}
+`)
+	if _, err := f.ApplyFixes("addctxparam"); err != nil {
+		t.Fatalf("ApplyFixes() error = %v", err)
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, "func F(ctx context.Context)") {
+		t.Errorf("addctxparam didn't add the parameter:\n%s", out)
+	}
+	if !strings.Contains(out, "doWork(ctx, x)") {
+		t.Errorf("addctxparam didn't thread ctx through the placeholder call:\n%s", out)
+	}
+}
+
+func TestApplyFixes_AddCtxParam_ExistingParamDifferentName(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F(c context.Context) {
+	doWork(context.TODO())
+}
+`)
+	if _, err := f.ApplyFixes("addctxparam"); err != nil {
+		t.Fatalf("ApplyFixes() error = %v", err)
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, "func F(c context.Context)") {
+		t.Errorf("addctxparam added a redundant context param:\n%s", out)
+	}
+	if !strings.Contains(out, "doWork(c)") {
+		t.Errorf("addctxparam didn't thread the existing param's own name through:\n%s", out)
+	}
+}
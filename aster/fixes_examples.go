@@ -0,0 +1,174 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"path"
+)
+
+// These two fixes are worked examples of the Fix API, in the spirit of
+// the standard library's gofix tool: each one targets one specific,
+// hardcoded migration rather than a configurable rewrite.
+
+func init() {
+	Register(Fix{
+		Name:  "contextpkgpath",
+		Date:  "2019-01-01",
+		Desc:  `Rewrites the "golang.org/x/net/context" import to "context" and updates qualified references.`,
+		Apply: rewriteContextImportFix,
+	})
+	Register(Fix{
+		Name:  "addctxparam",
+		Date:  "2019-01-01",
+		Desc:  "Adds a leading context.Context parameter (unless one exists) to functions that stand in a context.TODO()/context.Background() placeholder, and threads it through in place of the placeholder.",
+		Apply: addContextParamFix,
+	})
+}
+
+const (
+	oldContextPath = "golang.org/x/net/context"
+	newContextPath = "context"
+)
+
+// rewriteContextImportFix rewrites the pre-Go1.7 "golang.org/x/net/context"
+// import path to the standard library "context" package, and renames any
+// qualified references (x.Context, x.Background(), ...) that used its
+// inferred package name.
+func rewriteContextImportFix(f *File) (changed bool, err error) {
+	oldName, found := f.importNameForPath(oldContextPath)
+	if !found {
+		return false, nil
+	}
+	if !f.RewriteImport(oldContextPath, newContextPath) {
+		return false, nil
+	}
+	// An explicit local name survives RewriteImport, so only references
+	// through the inferred package name need renaming.
+	if newName := path.Base(newContextPath); oldName == path.Base(oldContextPath) && oldName != newName {
+		f.renameSelectorPkg(oldName, newName)
+	}
+	return true, nil
+}
+
+func (f *File) importNameForPath(importPath string) (name string, found bool) {
+	for _, imp := range f.Imports {
+		if imp.Path == importPath {
+			return imp.Name, true
+		}
+	}
+	return "", false
+}
+
+// renameSelectorPkg renames every package-qualified reference x.Sel,
+// where x is the bare package identifier oldName, to newName.
+func (f *File) renameSelectorPkg(oldName, newName string) {
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Name == oldName && id.Obj == nil {
+			id.Name = newName
+		}
+		return true
+	})
+}
+
+// addContextParamFix prepends a "ctx context.Context" parameter to every
+// FuncDecl whose body stands in a context.TODO() or context.Background()
+// placeholder, unless it already has a context.Context parameter, and
+// rewrites those placeholder calls to reference that parameter instead,
+// the common real-world follow-up once a function that used to invent
+// its own context is given a caller-supplied one.
+func addContextParamFix(f *File) (changed bool, err error) {
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		fd, ok := n.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			return true
+		}
+		placeholders := contextPlaceholderCalls(fd.Body)
+		if len(placeholders) == 0 {
+			return true
+		}
+		if fd.Type.Params == nil {
+			fd.Type.Params = &ast.FieldList{}
+		}
+		ctxName, ok := contextParamName(fd.Type.Params)
+		if !ok {
+			ctxName = "ctx"
+			ctxField := &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(ctxName)},
+				Type: &ast.SelectorExpr{
+					X:   ast.NewIdent("context"),
+					Sel: ast.NewIdent("Context"),
+				},
+			}
+			fd.Type.Params.List = append([]*ast.Field{ctxField}, fd.Type.Params.List...)
+		}
+		for _, call := range placeholders {
+			f.Replace(call, ast.NewIdent(ctxName))
+		}
+		changed = true
+		return true
+	})
+	if changed {
+		f.AddImport("context")
+	}
+	return changed, nil
+}
+
+// contextPlaceholderCalls returns every context.TODO() or
+// context.Background() call in body, the usual placeholders a function
+// reaches for before it's given a real context to thread through.
+func contextPlaceholderCalls(body *ast.BlockStmt) (calls []*ast.CallExpr) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || id.Name != "context" {
+			return true
+		}
+		if sel.Sel.Name == "TODO" || sel.Sel.Name == "Background" {
+			calls = append(calls, call)
+		}
+		return true
+	})
+	return calls
+}
+
+// contextParamName returns the name of fl's context.Context parameter,
+// if it already has one, so callers can thread through the name already
+// in scope instead of assuming it's called "ctx".
+func contextParamName(fl *ast.FieldList) (name string, found bool) {
+	for _, fld := range fl.List {
+		sel, ok := fld.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok || x.Name != "context" || len(fld.Names) == 0 {
+			continue
+		}
+		return fld.Names[0].Name, true
+	}
+	return "", false
+}
@@ -0,0 +1,283 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+)
+
+// AddImport adds the import path to the file, if it is not already
+// imported, and reports whether it added a new import.
+func (f *File) AddImport(path string) (added bool) {
+	return f.AddNamedImport("", path)
+}
+
+// AddNamedImport adds the import path to the file with the given local
+// name (use "_" for a blank import, "." for a dot import, or "" to let
+// the import use its package name), if it is not already imported under
+// that name, and reports whether it added a new import.
+func (f *File) AddNamedImport(name, path string) (added bool) {
+	if f.findImportSpec(name, path) != nil {
+		return false
+	}
+	spec := &ast.ImportSpec{
+		Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
+	}
+	if name != "" {
+		spec.Name = ast.NewIdent(name)
+	}
+	f.insertImportSpec(spec)
+	f.refreshImports()
+	return true
+}
+
+// DeleteImport deletes the import path from the file's import
+// declarations, and reports whether it deleted one.
+func (f *File) DeleteImport(path string) (deleted bool) {
+	return f.DeleteNamedImport("", path)
+}
+
+// DeleteNamedImport deletes the import with the given name and path from
+// the file's import declarations, and reports whether it deleted one.
+func (f *File) DeleteNamedImport(name, path string) (deleted bool) {
+	var removed []*ast.ImportSpec
+	var decls []ast.Decl
+	for _, decl := range f.File.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		var specs []ast.Spec
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if importPath(imp) == path && explicitName(imp) == name {
+				deleted = true
+				removed = append(removed, imp)
+				continue
+			}
+			specs = append(specs, imp)
+		}
+		if len(specs) > 0 || len(gen.Specs) == 0 {
+			gen.Specs = specs
+			decls = append(decls, gen)
+		}
+		// drop the GenDecl entirely once it is left empty
+	}
+	f.File.Decls = decls
+	if deleted {
+		f.deleteImportComments(removed)
+		f.deleteASTImport(name, path)
+		f.refreshImports()
+	}
+	return
+}
+
+// deleteImportComments removes the Doc and Comment groups of the given
+// removed import specs from f.File.Comments, so go/format doesn't
+// reattach a deleted import's comment to whatever now sits at its old
+// source position.
+func (f *File) deleteImportComments(removed []*ast.ImportSpec) {
+	if len(removed) == 0 {
+		return
+	}
+	drop := make(map[*ast.CommentGroup]bool, len(removed)*2)
+	for _, imp := range removed {
+		if imp.Doc != nil {
+			drop[imp.Doc] = true
+		}
+		if imp.Comment != nil {
+			drop[imp.Comment] = true
+		}
+	}
+	var comments []*ast.CommentGroup
+	for _, cg := range f.File.Comments {
+		if drop[cg] {
+			continue
+		}
+		comments = append(comments, cg)
+	}
+	f.File.Comments = comments
+}
+
+// RewriteImport rewrites the import path oldPath to newPath in the file's
+// import declarations, preserving any local name, and reports whether it
+// rewrote one.
+func (f *File) RewriteImport(oldPath, newPath string) (rewrote bool) {
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		imp, ok := n.(*ast.ImportSpec)
+		if !ok {
+			return true
+		}
+		if importPath(imp) == oldPath {
+			imp.Path.Value = strconv.Quote(newPath)
+			rewrote = true
+		}
+		return true
+	})
+	if rewrote {
+		f.refreshImports()
+	}
+	return
+}
+
+// UsesImport reports whether the file uses the import with the given
+// path, i.e. whether the import's local name is referenced anywhere in
+// the file outside of the import declaration itself. Blank and dot
+// imports are always reported as used.
+func (f *File) UsesImport(path string) (used bool) {
+	var name string
+	found := false
+	for _, spec := range f.File.Imports {
+		if importPath(spec) != path {
+			continue
+		}
+		found = true
+		name = importName(spec)
+		if name == "_" {
+			return true
+		}
+		if name == "." {
+			// Dot imports can't be distinguished from local
+			// identifiers, so conservatively assume usage.
+			return true
+		}
+	}
+	if !found {
+		return false
+	}
+	ast.Inspect(f.File, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+			used = true
+		}
+		return true
+	})
+	return
+}
+
+// findImportSpec returns the *ast.ImportSpec already importing path under
+// the explicit local name name ("" meaning no alias), or nil if there is
+// none.
+func (f *File) findImportSpec(name, path string) *ast.ImportSpec {
+	for _, spec := range f.File.Imports {
+		if importPath(spec) == path && explicitName(spec) == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+// insertImportSpec merges spec into the file's first import declaration
+// (opening a single, unparenthesized import into a grouped block) if the
+// file has one, or creates a new import declaration positioned right
+// after the package clause.
+func (f *File) insertImportSpec(spec *ast.ImportSpec) {
+	for _, decl := range f.File.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if len(gen.Specs) == 1 && !gen.Lparen.IsValid() {
+			// A single, non-parenthesized import: open it up into a
+			// grouped block so the new spec can be appended cleanly.
+			gen.Lparen = gen.TokPos + token.Pos(len("import"))
+		}
+		gen.Specs = append(gen.Specs, spec)
+		sortImportSpecs(gen.Specs)
+		f.File.Imports = append(f.File.Imports, spec)
+		return
+	}
+	gen := &ast.GenDecl{
+		TokPos: f.File.Name.End() + 1,
+		Tok:    token.IMPORT,
+		Lparen: token.NoPos,
+		Specs:  []ast.Spec{spec},
+	}
+	decls := make([]ast.Decl, 0, len(f.File.Decls)+1)
+	decls = append(decls, gen)
+	decls = append(decls, f.File.Decls...)
+	f.File.Decls = decls
+	f.File.Imports = append(f.File.Imports, spec)
+}
+
+// deleteASTImport removes the matching spec from f.File.Imports, the
+// flat list go/ast keeps alongside the grouped Decls.
+func (f *File) deleteASTImport(name, path string) {
+	var imports []*ast.ImportSpec
+	for _, spec := range f.File.Imports {
+		if importPath(spec) == path && explicitName(spec) == name {
+			continue
+		}
+		imports = append(imports, spec)
+	}
+	f.File.Imports = imports
+}
+
+// refreshImports rebuilds f.Imports from the current *ast.File state, so
+// that callers who mutated the import declarations see an up-to-date
+// view through the existing Import-keyed APIs.
+func (f *File) refreshImports() {
+	imports := make([]*Import, 0, len(f.File.Imports))
+	for _, spec := range f.File.Imports {
+		imports = append(imports, &Import{
+			Name: importName(spec),
+			Path: importPath(spec),
+		})
+	}
+	f.Imports = imports
+}
+
+func sortImportSpecs(specs []ast.Spec) {
+	sort.Slice(specs, func(i, j int) bool {
+		return importPath(specs[i].(*ast.ImportSpec)) < importPath(specs[j].(*ast.ImportSpec))
+	})
+}
+
+// importPath returns the unquoted import path of spec.
+func importPath(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// importName returns the effective local name of spec: its explicit
+// name if any ("_" and "." included for blank/dot imports), otherwise
+// the last element of its import path, mirroring the inferred package
+// name that LookupImports matches against.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return path.Base(importPath(spec))
+}
+
+// explicitName returns spec's explicit local alias, or "" if it has
+// none (including "_" and "." which are themselves explicit aliases).
+func explicitName(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return ""
+	}
+	return spec.Name.Name
+}
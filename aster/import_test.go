@@ -0,0 +1,143 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// parseTestFile parses src into a standalone *File, with f.Imports
+// populated the same way a freshly-loaded file would be, but with no
+// enclosing Package/Module (f.pkg stays nil).
+func parseTestFile(t *testing.T, src string) *File {
+	t.Helper()
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	f := &File{File: astFile, FileSet: fset}
+	f.refreshImports()
+	f.collectNodes(true)
+	return f
+}
+
+func formatTestFile(t *testing.T, f *File) string {
+	t.Helper()
+	var buf strings.Builder
+	if err := format.Node(&buf, f.FileSet, f.File); err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	return buf.String()
+}
+
+func TestDeleteImport_DropsOwnComment(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+import (
+	"fmt" // used for printing
+	"os"
+)
+`)
+	if !f.DeleteImport("fmt") {
+		t.Fatalf("DeleteImport(%q) = false, want true", "fmt")
+	}
+	out := formatTestFile(t, f)
+	if strings.Contains(out, "used for printing") {
+		t.Errorf("deleted import's comment survived reprint:\n%s", out)
+	}
+	if !strings.Contains(out, `"os"`) {
+		t.Errorf("surviving import dropped from reprint:\n%s", out)
+	}
+}
+
+func TestDeleteNamedImport_DottedBlankRenamed(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+import (
+	. "fmt"
+	_ "os"
+	str "strings"
+)
+`)
+	cases := []struct {
+		name, path string
+	}{
+		{".", "fmt"},
+		{"_", "os"},
+		{"str", "strings"},
+	}
+	for _, c := range cases {
+		if !f.DeleteNamedImport(c.name, c.path) {
+			t.Errorf("DeleteNamedImport(%q, %q) = false, want true", c.name, c.path)
+		}
+	}
+	if len(f.Imports) != 0 {
+		t.Errorf("f.Imports = %v, want empty", f.Imports)
+	}
+	out := formatTestFile(t, f)
+	if strings.Contains(out, "import") {
+		t.Errorf("empty import block survived reprint:\n%s", out)
+	}
+}
+
+func TestAddNamedImport(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+import "os"
+`)
+	if !f.AddNamedImport("", "fmt") {
+		t.Fatalf("AddNamedImport(\"\", \"fmt\") = false, want true")
+	}
+	if f.AddNamedImport("", "fmt") {
+		t.Errorf("AddNamedImport(\"\", \"fmt\") second call = true, want false (already imported)")
+	}
+	if !f.AddNamedImport("_", "fmt") {
+		t.Errorf("AddNamedImport(\"_\", \"fmt\") = false, want true (blank import is distinct)")
+	}
+	var gotFmt bool
+	for _, imp := range f.Imports {
+		if imp.Path == "fmt" {
+			gotFmt = true
+		}
+	}
+	if !gotFmt {
+		t.Errorf("f.Imports = %v, want to contain fmt", f.Imports)
+	}
+	if _, err := format.Source([]byte(formatTestFile(t, f))); err != nil {
+		t.Errorf("reprinted source doesn't parse: %v", err)
+	}
+}
+
+func TestRewriteImport(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+import "golang.org/x/net/context"
+`)
+	if !f.RewriteImport("golang.org/x/net/context", "context") {
+		t.Fatalf("RewriteImport = false, want true")
+	}
+	out := formatTestFile(t, f)
+	if !strings.Contains(out, `"context"`) {
+		t.Errorf("rewritten import missing from reprint:\n%s", out)
+	}
+	if strings.Contains(out, "golang.org/x/net/context") {
+		t.Errorf("old import path survived reprint:\n%s", out)
+	}
+}
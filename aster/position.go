@@ -0,0 +1,111 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// PosFromOffset converts a zero-based byte offset into the file's
+// source text to the equivalent token.Pos, for callers that only have a
+// byte offset (e.g. from an editor or LSP request) on hand.
+func (f *File) PosFromOffset(offset int) token.Pos {
+	tokFile := f.FileSet.File(f.File.Pos())
+	if tokFile == nil || offset < 0 || offset > tokFile.Size() {
+		return token.NoPos
+	}
+	return tokFile.Pos(offset)
+}
+
+// PathEnclosingInterval returns the path to the innermost ast.Node that
+// encloses the source interval [start, end), and reports whether that
+// node's interval exactly matches [start, end). path[0] is the
+// innermost node, path[len(path)-1] is f.File itself.
+//
+// It returns (nil, false) if the file contains no node enclosing the
+// interval, which can only happen if the interval lies outside the
+// bounds of the file.
+func (f *File) PathEnclosingInterval(start, end token.Pos) (path []ast.Node, exact bool) {
+	if start > end {
+		start, end = end, start
+	}
+	var visit func(n ast.Node) []ast.Node
+	visit = func(n ast.Node) []ast.Node {
+		nStart, nEnd := n.Pos(), n.End()
+		if !nStart.IsValid() || !nEnd.IsValid() || nStart > start || end > nEnd {
+			return nil
+		}
+		for _, child := range childrenOf(n) {
+			if inner := visit(child); inner != nil {
+				return append(inner, n)
+			}
+		}
+		return []ast.Node{n}
+	}
+	path = visit(f.File)
+	if path == nil {
+		return nil, false
+	}
+	innermost := path[0]
+	exact = innermost.Pos() == start && innermost.End() == end
+	return path, exact
+}
+
+// childrenOf returns the direct AST children of n in source order,
+// using ast.Inspect's single-level semantics rather than recursing, so
+// PathEnclosingInterval can descend one level at a time and stop at the
+// innermost enclosing node.
+func childrenOf(n ast.Node) (children []ast.Node) {
+	ast.Inspect(n, func(child ast.Node) bool {
+		if child == nil || child == n {
+			return true
+		}
+		children = append(children, child)
+		return false
+	})
+	return
+}
+
+// EnclosingFunc returns the innermost FuncNode (FuncDecl or FuncLit)
+// whose declaration (signature or body) contains pos, and reports
+// whether one was found.
+func (f *File) EnclosingFunc(pos token.Pos) (fn FuncNode, found bool) {
+	path, _ := f.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			if fn, found = f.Funcs[n.Pos()]; found {
+				return
+			}
+		}
+	}
+	return nil, false
+}
+
+// EnclosingType returns the innermost TypeNode whose declaration
+// contains pos, and reports whether one was found.
+func (f *File) EnclosingType(pos token.Pos) (t TypeNode, found bool) {
+	path, _ := f.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.TypeSpec, *ast.StructType, *ast.InterfaceType:
+			if t, found = f.Types[n.Pos()]; found {
+				return
+			}
+		}
+	}
+	return nil, false
+}
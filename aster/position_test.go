@@ -0,0 +1,78 @@
+// Copyright 2018 henrylee2cn. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aster
+
+import (
+	"go/ast"
+	"testing"
+)
+
+func TestPathEnclosingInterval_SingleNode(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	x := 1
+	_ = x
+}
+`)
+	fd := f.File.Decls[0].(*ast.FuncDecl)
+	stmt := fd.Body.List[0]
+	path, exact := f.PathEnclosingInterval(stmt.Pos(), stmt.End())
+	if len(path) == 0 {
+		t.Fatalf("PathEnclosingInterval() returned no path")
+	}
+	if path[0] != ast.Node(stmt) {
+		t.Errorf("path[0] = %T, want the exact statement", path[0])
+	}
+	if !exact {
+		t.Errorf("exact = false, want true for a query matching the node's own bounds")
+	}
+}
+
+// TestPathEnclosingInterval_SpanningSiblings is the editor/hover case: a
+// selection that spans two sibling statements must resolve to their
+// common enclosing block, not to a node nested inside whichever sibling
+// the descent happens to reach first.
+func TestPathEnclosingInterval_SpanningSiblings(t *testing.T) {
+	f := parseTestFile(t, `package p
+
+func F() {
+	x := 1
+	y := 2
+	_, _ = x, y
+}
+`)
+	fd := f.File.Decls[0].(*ast.FuncDecl)
+	first, second := fd.Body.List[0], fd.Body.List[1]
+	path, exact := f.PathEnclosingInterval(first.Pos(), second.End())
+	if len(path) == 0 {
+		t.Fatalf("PathEnclosingInterval() returned no path")
+	}
+	if _, ok := path[0].(*ast.BlockStmt); !ok {
+		t.Errorf("path[0] = %T, want *ast.BlockStmt enclosing both statements", path[0])
+	}
+	if exact {
+		t.Errorf("exact = true, want false: the block is wider than the query interval")
+	}
+}
+
+func TestPathEnclosingInterval_OutOfBounds(t *testing.T) {
+	f := parseTestFile(t, `package p
+`)
+	end := f.File.End()
+	if path, _ := f.PathEnclosingInterval(end+10, end+20); path != nil {
+		t.Errorf("PathEnclosingInterval() for an out-of-bounds interval = %v, want nil", path)
+	}
+}